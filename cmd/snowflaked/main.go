@@ -0,0 +1,146 @@
+/**
+ * snowflaked 是一个独立的 id 分配服务进程：把一个 snowflake.Layout + MachineIDProvider
+ * 组合通过 gRPC 和 HTTP+JSON 暴露出来，供多语言的调用方共用，同时导出 Prometheus 指标。
+ *
+ * 用法举例：
+ *   snowflaked -addr :9000 -layout twitter -dc-id 1 -machine-id 2
+ *   snowflaked -addr :9000 -layout twitter -auto=hostident
+ *
+ * 也可以完全用环境变量配置：SNOWFLAKE_ADDR、SNOWFLAKE_LAYOUT、SNOWFLAKE_DC_ID、
+ * SNOWFLAKE_MACHINE_ID（见 golang.EnvProvider）。
+ */
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"os"
+
+	snowflake "github.com/dxc520/snowflake/golang"
+	snowflakeserver "github.com/dxc520/snowflake/golang/server"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"google.golang.org/grpc"
+)
+
+func main() {
+	var (
+		addr         = flag.String("addr", envOr("SNOWFLAKE_ADDR", ":9000"), "gRPC 监听地址")
+		httpAddr     = flag.String("http-addr", envOr("SNOWFLAKE_HTTP_ADDR", ":9001"), "HTTP+JSON 与 /metrics 监听地址")
+		layoutName   = flag.String("layout", envOr("SNOWFLAKE_LAYOUT", "current"), "位分配方案：twitter、current、high-concurrency")
+		dataCenterId = flag.Int64("dc-id", -1, "数据中心 id；不设置则尝试 -auto 或环境变量")
+		machineId    = flag.Int64("machine-id", -1, "机器 id；不设置则尝试 -auto 或环境变量")
+		auto         = flag.String("auto", "", "自动分配来源：hostident（MAC/hostname 推导）或 env（读环境变量）")
+	)
+	flag.Parse()
+
+	layout, err := resolveLayout(*layoutName)
+	if err != nil {
+		log.Fatalf("snowflaked: %s", err.Error())
+	}
+
+	allocator, err := resolveAllocator(layout, *dataCenterId, *machineId, *auto)
+	if err != nil {
+		log.Fatalf("snowflaked: %s", err.Error())
+	}
+
+	svc := snowflakeserver.NewSnowflake(allocator, layout)
+
+	go serveHTTP(*httpAddr, svc)
+	serveGRPC(*addr, svc)
+}
+
+func resolveLayout(name string) (snowflake.Layout, error) {
+	switch name {
+	case "twitter":
+		return snowflake.LayoutTwitter, nil
+	case "current", "":
+		return snowflake.LayoutCurrent, nil
+	case "high-concurrency":
+		return snowflake.LayoutHighConcurrency, nil
+	default:
+		return snowflake.Layout{}, fmt.Errorf("unknown -layout %q", name)
+	}
+}
+
+func resolveAllocator(layout snowflake.Layout, dataCenterId, machineId int64, auto string) (*snowflakeInstance, error) {
+	opts := []snowflake.Option{
+		snowflake.WithLayout(layout),
+		snowflake.WithSequenceExhaustedHook(snowflakeserver.ObserveSequenceExhausted),
+	}
+
+	if dataCenterId >= 0 && machineId >= 0 {
+		instance, err := snowflake.NewInstance(dataCenterId, machineId, opts...)
+		if err != nil {
+			return nil, err
+		}
+		return &snowflakeInstance{&instance}, nil
+	}
+
+	var provider snowflake.MachineIDProvider
+	switch auto {
+	case "hostident":
+		maxDataCenterNum := int64(1) << layout.DataCenterBits
+		maxMachineNum := int64(1) << layout.MachineBits
+		provider = snowflake.NewHostIdentityProvider(maxDataCenterNum, maxMachineNum)
+	case "env", "":
+		provider = snowflake.NewEnvProvider()
+	default:
+		return nil, fmt.Errorf("unknown -auto %q", auto)
+	}
+
+	instance, err := snowflake.NewInstanceAuto(context.Background(), provider, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &snowflakeInstance{instance}, nil
+}
+
+// snowflakeInstance 适配 snowflake 包里未导出的实例类型，满足 server.Allocator 接口。
+// snowflake.NewInstance/NewInstanceAuto 返回的具体类型未导出，外部包拿不到类型名字，
+// 但可以用 := 持有它的指针，赋给一个匿名接口字段。
+type snowflakeInstance struct {
+	inner interface {
+		NextId() (int64, error)
+		BatchNextIds(n int) ([]int64, error)
+	}
+}
+
+func (s *snowflakeInstance) NextId() (int64, error)              { return s.inner.NextId() }
+func (s *snowflakeInstance) BatchNextIds(n int) ([]int64, error) { return s.inner.BatchNextIds(n) }
+
+func serveGRPC(addr string, svc *snowflakeserver.Snowflake) {
+	lis, err := net.Listen("tcp", addr)
+	if err != nil {
+		log.Fatalf("snowflaked: listen %s: %s", addr, err.Error())
+	}
+
+	s := grpc.NewServer()
+	snowflakeserver.RegisterSnowflakeServer(s, svc)
+
+	log.Printf("snowflaked: gRPC listening on %s", addr)
+	if err := s.Serve(lis); err != nil {
+		log.Fatalf("snowflaked: serve grpc: %s", err.Error())
+	}
+}
+
+func serveHTTP(addr string, svc *snowflakeserver.Snowflake) {
+	mux := http.NewServeMux()
+	mux.Handle("/", svc.HTTPHandler())
+	mux.Handle("/metrics", promhttp.Handler())
+
+	log.Printf("snowflaked: HTTP listening on %s", addr)
+	if err := http.ListenAndServe(addr, mux); err != nil {
+		log.Fatalf("snowflaked: serve http: %s", err.Error())
+	}
+}
+
+func envOr(name, fallback string) string {
+	if v := os.Getenv(name); v != "" {
+		return v
+	}
+	return fallback
+}
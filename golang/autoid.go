@@ -0,0 +1,158 @@
+package snowflake
+
+import (
+	"context"
+	"crypto/sha1"
+	"encoding/binary"
+	"errors"
+	"net"
+	"os"
+	"strconv"
+	"sync/atomic"
+)
+
+/**
+ * MachineIDProvider 是 DataCenterId/MachineId 的获取方式的抽象。手工指定
+ * (dataCenterId, machineId) 是一个常见的运维事故源：两个实例如果被手滑配成
+ * 同一对值，就会悄悄地生成冲突的 id。实现这个接口可以把这件事交给机器身份
+ * 推导（见 HostIdentityProvider）或者 etcd/Redis/ZooKeeper 之类的协调后端。
+ *
+ * Acquire 返回的 lease 通道约定为：只要租约有效就不会有任何事件；一旦协调后端
+ * 判定租约丢失（续约失败、会话过期等），实现者应当关闭这个通道。
+ * NewInstanceAuto 会监听它，并在租约丢失后让该实例停止签发新 id。
+ */
+type MachineIDProvider interface {
+	Acquire(ctx context.Context) (dataCenterId, machineId int64, lease <-chan struct{}, err error)
+}
+
+// NewInstanceAuto 通过 provider 解析出 (dataCenterId, machineId)，用当前（或 opts 里
+// WithLayout 指定的）Layout 校验这对值是否落在范围内，然后像 NewInstance 一样构造实例。
+// 它额外监听 provider 返回的 lease 通道：一旦租约丢失，后续的 NextId/NextID/BatchNextIds
+// 调用都会返回错误，调用方据此知道自己需要停止签发 id（通常是重新获取身份并重建实例）。
+//
+// 返回的是 *snowFlakeId 而不是 NewInstance 那样的值类型：后台 goroutine 需要在租约
+// 丢失时修改同一个实例，必须共享同一块内存。
+func NewInstanceAuto(ctx context.Context, provider MachineIDProvider, opts ...Option) (*snowFlakeId, error) {
+	dataCenterId, machineId, lease, err := provider.Acquire(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	sf, err := NewInstance(dataCenterId, machineId, opts...)
+	if err != nil {
+		return &sf, err
+	}
+
+	go func() {
+		select {
+		case <-lease:
+			atomic.StoreInt32(&sf.leaseLost, 1)
+		case <-ctx.Done():
+			atomic.StoreInt32(&sf.leaseLost, 1)
+		}
+	}()
+
+	return &sf, nil
+}
+
+// EnvProvider 从 SNOWFLAKE_DC_ID / SNOWFLAKE_MACHINE_ID 环境变量里读取
+// dataCenterId/machineId。这是一对静态配置，没有协调后端，lease 通道永远不会关闭。
+type EnvProvider struct {
+	DataCenterEnv string // 默认 SNOWFLAKE_DC_ID
+	MachineEnv    string // 默认 SNOWFLAKE_MACHINE_ID
+}
+
+// NewEnvProvider 返回一个读取默认环境变量名的 EnvProvider。
+func NewEnvProvider() *EnvProvider {
+	return &EnvProvider{DataCenterEnv: "SNOWFLAKE_DC_ID", MachineEnv: "SNOWFLAKE_MACHINE_ID"}
+}
+
+func (e *EnvProvider) Acquire(ctx context.Context) (int64, int64, <-chan struct{}, error) {
+	dcEnv, machineEnv := e.DataCenterEnv, e.MachineEnv
+	if dcEnv == "" {
+		dcEnv = "SNOWFLAKE_DC_ID"
+	}
+	if machineEnv == "" {
+		machineEnv = "SNOWFLAKE_MACHINE_ID"
+	}
+
+	dataCenterId, err := parseEnvInt64(dcEnv)
+	if err != nil {
+		return 0, 0, nil, err
+	}
+	machineId, err := parseEnvInt64(machineEnv)
+	if err != nil {
+		return 0, 0, nil, err
+	}
+
+	// 静态配置，没有租约可言，返回一个永远不会被关闭的通道。
+	return dataCenterId, machineId, make(chan struct{}), nil
+}
+
+func parseEnvInt64(name string) (int64, error) {
+	v := os.Getenv(name)
+	if v == "" {
+		return 0, errors.New("snowflake: environment variable " + name + " is not set")
+	}
+	return strconv.ParseInt(v, 10, 64)
+}
+
+// HostIdentityProvider 从本机身份（主网卡 MAC 地址，拿不到就退化到 hostname）
+// 推导出 dataCenterId/machineId。MaxDataCenterNum/MaxMachineNum 应该和实际使用的
+// Layout 的上限一致（即 2^DataCenterBits、2^MachineBits），推导结果会对它们取模。
+//
+// 这是进程本地的确定性推导，不做跨实例协调，所以仍然可能撞上另一台凑巧算出同一个
+// 哈希值的机器；对强一致性的需求，应该实现一个基于 etcd/Redis/ZooKeeper 等协调后端
+// 的 MachineIDProvider。
+type HostIdentityProvider struct {
+	MaxDataCenterNum int64
+	MaxMachineNum    int64
+}
+
+// NewHostIdentityProvider 构造一个 HostIdentityProvider，上限通常取自
+// Layout 的 2^DataCenterBits / 2^MachineBits。
+func NewHostIdentityProvider(maxDataCenterNum, maxMachineNum int64) *HostIdentityProvider {
+	return &HostIdentityProvider{MaxDataCenterNum: maxDataCenterNum, MaxMachineNum: maxMachineNum}
+}
+
+func (h *HostIdentityProvider) Acquire(ctx context.Context) (int64, int64, <-chan struct{}, error) {
+	identity, err := primaryHostIdentity()
+	if err != nil {
+		return 0, 0, nil, err
+	}
+
+	sum := sha1.Sum(identity)
+	// 用哈希的前 4 个字节和紧随其后的 4 个字节分别推导数据中心和机器号，让它们尽量不相关。
+	dataCenterId := int64(binary.BigEndian.Uint32(sum[0:4])) % h.MaxDataCenterNum
+	machineId := int64(binary.BigEndian.Uint32(sum[4:8])) % h.MaxMachineNum
+	if dataCenterId < 0 {
+		dataCenterId += h.MaxDataCenterNum
+	}
+	if machineId < 0 {
+		machineId += h.MaxMachineNum
+	}
+
+	return dataCenterId, machineId, make(chan struct{}), nil
+}
+
+// primaryHostIdentity 优先使用主网卡的 MAC 地址作为机器身份，拿不到（比如容器里
+// 没有物理网卡）就退化成 hostname。
+func primaryHostIdentity() ([]byte, error) {
+	if ifaces, err := net.Interfaces(); err == nil {
+		for _, iface := range ifaces {
+			if len(iface.HardwareAddr) == 0 {
+				continue
+			}
+			if iface.Flags&net.FlagLoopback != 0 {
+				continue
+			}
+			return iface.HardwareAddr, nil
+		}
+	}
+
+	hostname, err := os.Hostname()
+	if err != nil {
+		return nil, err
+	}
+	return []byte(hostname), nil
+}
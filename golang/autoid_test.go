@@ -0,0 +1,58 @@
+package snowflake
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestEnvProvider(t *testing.T) {
+	os.Setenv("SNOWFLAKE_DC_ID", "2")
+	os.Setenv("SNOWFLAKE_MACHINE_ID", "3")
+	defer os.Unsetenv("SNOWFLAKE_DC_ID")
+	defer os.Unsetenv("SNOWFLAKE_MACHINE_ID")
+
+	instance, err := NewInstanceAuto(context.Background(), NewEnvProvider())
+	if err != nil {
+		t.Fatalf("new instance auto is error: %s", err.Error())
+	}
+	if instance.DataCenterId != 2 || instance.MachineId != 3 {
+		t.Fatalf("expected dc=2 machine=3, got dc=%d machine=%d", instance.DataCenterId, instance.MachineId)
+	}
+
+	if _, err := instance.NextId(); err != nil {
+		t.Fatalf("next id is error: %s", err.Error())
+	}
+}
+
+func TestNewInstanceAutoStopsOnLeaseLoss(t *testing.T) {
+	lease := make(chan struct{})
+	provider := &fakeLeaseProvider{dataCenterId: 1, machineId: 1, lease: lease}
+
+	instance, err := NewInstanceAuto(context.Background(), provider)
+	if err != nil {
+		t.Fatalf("new instance auto is error: %s", err.Error())
+	}
+
+	if _, err := instance.NextId(); err != nil {
+		t.Fatalf("expected next id to succeed before lease loss: %s", err.Error())
+	}
+
+	close(lease)
+	// 后台 goroutine 需要一点时间来处理通道关闭事件。
+	time.Sleep(50 * time.Millisecond)
+
+	if _, err := instance.NextId(); err == nil {
+		t.Fatalf("expected next id to fail after lease loss")
+	}
+}
+
+type fakeLeaseProvider struct {
+	dataCenterId, machineId int64
+	lease                   <-chan struct{}
+}
+
+func (f *fakeLeaseProvider) Acquire(ctx context.Context) (int64, int64, <-chan struct{}, error) {
+	return f.dataCenterId, f.machineId, f.lease, nil
+}
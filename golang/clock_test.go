@@ -0,0 +1,123 @@
+package snowflake
+
+import (
+	"sync"
+	"testing"
+)
+
+// fakeClock 是一个可以被测试任意拨动的毫秒时钟，配合 WithClockFunc 注入。
+type fakeClock struct {
+	mu  sync.Mutex
+	now int64
+}
+
+func (c *fakeClock) Now() int64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) Set(ms int64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = ms
+}
+
+func TestWithClockFunc(t *testing.T) {
+	clock := &fakeClock{now: defaultEpoch + 1_000_000}
+
+	instance, err := NewInstance(1, 1, WithClockFunc(clock.Now))
+	if err != nil {
+		t.Fatalf("new instance is error: %s", err.Error())
+	}
+
+	id, err := instance.NextId()
+	if err != nil {
+		t.Fatalf("next id is error: %s", err.Error())
+	}
+
+	got := instance.Layout.ParseDateTime(id)
+	if got != clock.now {
+		t.Fatalf("expected id to embed injected clock time %d, got %d", clock.now, got)
+	}
+}
+
+func TestWithMaxBackwardMillisWaitsInsteadOfErroring(t *testing.T) {
+	clock := &fakeClock{now: defaultEpoch + 1_000_000}
+
+	instance, err := NewInstance(1, 1, WithClockFunc(clock.Now), WithMaxBackwardMillis(50))
+	if err != nil {
+		t.Fatalf("new instance is error: %s", err.Error())
+	}
+
+	if _, err := instance.NextId(); err != nil {
+		t.Fatalf("first next id is error: %s", err.Error())
+	}
+
+	// 回拨 20ms，在 50ms 的容忍范围内：NextId 应该自旋等到墙钟（被测试拨回来）追上，
+	// 而不是报错。用一个 goroutine 在短暂延迟后把时钟拨回来，模拟"墙钟追上"。
+	clock.Set(clock.Now() - 20)
+
+	done := make(chan struct{})
+	var nextErr error
+	go func() {
+		_, nextErr = instance.NextId()
+		close(done)
+	}()
+
+	// 确认 NextId 还在自旋等待，而不是已经用回拨后的时间立刻返回了结果。
+	select {
+	case <-done:
+		t.Fatalf("expected NextId to block waiting for the clock to catch up")
+	default:
+	}
+
+	clock.Set(clock.now + 30) // 追上并超过上一次的 LastTimeStamp
+
+	<-done
+	if nextErr != nil {
+		t.Fatalf("expected no error once the clock caught up, got: %s", nextErr.Error())
+	}
+}
+
+func TestWithLogicalClockFallbackAdvancesForward(t *testing.T) {
+	clock := &fakeClock{now: defaultEpoch + 1_000_000}
+
+	instance, err := NewInstance(1, 1,
+		WithClockFunc(clock.Now),
+		WithMaxBackwardMillis(10),
+		WithLogicalClockFallback(true),
+	)
+	if err != nil {
+		t.Fatalf("new instance is error: %s", err.Error())
+	}
+
+	if _, err := instance.NextId(); err != nil {
+		t.Fatalf("first next id is error: %s", err.Error())
+	}
+
+	// 回拨 1000ms，远超过 10ms 的容忍范围，并且此后时钟再也不会追上——
+	// 强制触发逻辑时钟回退模式。
+	clock.Set(clock.now - 1000)
+
+	seen := make(map[int64]bool)
+	var lastTimeStamp int64 = -1
+	// 生成的数量超过单毫秒的序列号上限，逼着逻辑时钟至少推进一次。
+	count := int(instance.Layout.maxSequence) + 10
+	for i := 0; i < count; i++ {
+		id, err := instance.NextId()
+		if err != nil {
+			t.Fatalf("next id %d is error: %s", i, err.Error())
+		}
+		if seen[id] {
+			t.Fatalf("duplicate id generated under logical clock fallback: %d", id)
+		}
+		seen[id] = true
+
+		ts := instance.Layout.ParseDateTime(id)
+		if ts < lastTimeStamp {
+			t.Fatalf("logical clock moved backwards: %d -> %d", lastTimeStamp, ts)
+		}
+		lastTimeStamp = ts
+	}
+}
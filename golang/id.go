@@ -0,0 +1,233 @@
+package snowflake
+
+import (
+	"database/sql/driver"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+)
+
+/**
+ * SnowflakeID 是 int64 形式的 id 的一个值类型封装，提供了多种字符串编码方式。
+ * 引入它主要是为了解决 JSON：JavaScript 的 Number 只有 53 位精度，直接把 int64
+ * 的 id 塞进 JSON 数字字段，前端拿到手可能已经被四舍五入，所以 MarshalJSON 总是
+ * 输出一个带引号的十进制字符串。
+ */
+type SnowflakeID int64
+
+const encodeBase32Map = "ybndrfg8ejkmcpqxot1uwisza345h769"
+const encodeBase58Map = "123456789abcdefghijkmnopqrstuvwxyzABCDEFGHJKLMNPQRSTUVWXYZ"
+
+var decodeBase32Map [256]byte
+var decodeBase58Map [256]byte
+
+func init() {
+	for i := 0; i < len(decodeBase32Map); i++ {
+		decodeBase32Map[i] = 0xFF
+	}
+	for i := 0; i < len(encodeBase32Map); i++ {
+		decodeBase32Map[encodeBase32Map[i]] = byte(i)
+	}
+
+	for i := 0; i < len(decodeBase58Map); i++ {
+		decodeBase58Map[i] = 0xFF
+	}
+	for i := 0; i < len(encodeBase58Map); i++ {
+		decodeBase58Map[encodeBase58Map[i]] = byte(i)
+	}
+}
+
+// NextID 是 NextId 的同义方法，返回 SnowflakeID 值类型而不是裸 int64，
+// 不影响 NextId 原来的签名，方便新代码直接拿到带编码方法的类型。
+func (p *snowFlakeId) NextID() (SnowflakeID, error) {
+	id, err := p.NextId()
+	return SnowflakeID(id), err
+}
+
+// String 返回十进制表示，和 int64 的默认打印一致。
+func (f SnowflakeID) String() string {
+	return strconv.FormatInt(int64(f), 10)
+}
+
+// Base2 返回二进制表示。
+func (f SnowflakeID) Base2() string {
+	return strconv.FormatInt(int64(f), 2)
+}
+
+// Base32 使用去除了易混淆字符的 32 进制字母表编码。
+//
+// 合法的 id（NextId 生成的）符号位恒为 0，不会是负数；这里仍然按 uint64 的
+// 位模式编码，这样即便 SnowflakeID 是经 Scan/UnmarshalJSON/UnmarshalBinary
+// 灌入的非法负数，也只会编码出一个不对应任何合法 id 的奇怪字符串，而不会因为
+// 对负数取模/下标越界而 panic。
+func (f SnowflakeID) Base32() string {
+	u := uint64(f)
+	if u < 32 {
+		return string(encodeBase32Map[u])
+	}
+
+	b := make([]byte, 0, 13)
+	for u >= 32 {
+		b = append(b, encodeBase32Map[u%32])
+		u /= 32
+	}
+	b = append(b, encodeBase32Map[u])
+
+	for x, y := 0, len(b)-1; x < y; x, y = x+1, y-1 {
+		b[x], b[y] = b[y], b[x]
+	}
+	return string(b)
+}
+
+// Base58 使用 Flickr 风格（小写字母在大写字母之前）的 58 进制字母表编码
+// （去掉了 0/O/I/l 等易混淆字符）。
+//
+// 和 Base32 一样按 uint64 位模式编码，避免负数 SnowflakeID 导致下标越界 panic。
+func (f SnowflakeID) Base58() string {
+	u := uint64(f)
+	if u < 58 {
+		return string(encodeBase58Map[u])
+	}
+
+	b := make([]byte, 0, 11)
+	for u >= 58 {
+		b = append(b, encodeBase58Map[u%58])
+		u /= 58
+	}
+	b = append(b, encodeBase58Map[u])
+
+	for x, y := 0, len(b)-1; x < y; x, y = x+1, y-1 {
+		b[x], b[y] = b[y], b[x]
+	}
+	return string(b)
+}
+
+// Base64 对十进制字符串做标准 base64 编码。
+func (f SnowflakeID) Base64() string {
+	return base64.StdEncoding.EncodeToString([]byte(f.String()))
+}
+
+// ParseString 把 String() 产生的十进制字符串解析回 SnowflakeID。
+func ParseString(id string) (SnowflakeID, error) {
+	i, err := strconv.ParseInt(id, 10, 64)
+	return SnowflakeID(i), err
+}
+
+// ParseBase2 把 Base2() 产生的二进制字符串解析回 SnowflakeID。
+func ParseBase2(id string) (SnowflakeID, error) {
+	i, err := strconv.ParseInt(id, 2, 64)
+	return SnowflakeID(i), err
+}
+
+// ParseBase32 把 Base32() 产生的字符串解析回 SnowflakeID。
+func ParseBase32(id string) (SnowflakeID, error) {
+	var result int64
+	for _, c := range []byte(id) {
+		b := decodeBase32Map[c]
+		if b == 0xFF {
+			return 0, fmt.Errorf("snowflake: invalid base32 character %q", c)
+		}
+		result = result*32 + int64(b)
+	}
+	return SnowflakeID(result), nil
+}
+
+// ParseBase58 把 Base58() 产生的字符串解析回 SnowflakeID。
+func ParseBase58(id string) (SnowflakeID, error) {
+	var result int64
+	for _, c := range []byte(id) {
+		b := decodeBase58Map[c]
+		if b == 0xFF {
+			return 0, fmt.Errorf("snowflake: invalid base58 character %q", c)
+		}
+		result = result*58 + int64(b)
+	}
+	return SnowflakeID(result), nil
+}
+
+// ParseBase64 把 Base64() 产生的字符串解析回 SnowflakeID。
+func ParseBase64(id string) (SnowflakeID, error) {
+	b, err := base64.StdEncoding.DecodeString(id)
+	if err != nil {
+		return 0, err
+	}
+	return ParseString(string(b))
+}
+
+// MarshalJSON 把 id 编码为带引号的十进制字符串，避免 JavaScript Number 的
+// 53 位精度限制把 int64 的 id 截断或舍入。
+func (f SnowflakeID) MarshalJSON() ([]byte, error) {
+	return json.Marshal(f.String())
+}
+
+// UnmarshalJSON 接受字符串形式（推荐）或裸数字形式的 id。
+func (f *SnowflakeID) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err == nil {
+		id, err := ParseString(s)
+		if err != nil {
+			return err
+		}
+		*f = id
+		return nil
+	}
+
+	var i int64
+	if err := json.Unmarshal(data, &i); err != nil {
+		return err
+	}
+	*f = SnowflakeID(i)
+	return nil
+}
+
+// MarshalBinary 编码为 8 字节大端序，供需要二进制格式的场景（如 gob、缓存）使用。
+func (f SnowflakeID) MarshalBinary() ([]byte, error) {
+	b := make([]byte, 8)
+	binary.BigEndian.PutUint64(b, uint64(f))
+	return b, nil
+}
+
+// UnmarshalBinary 解码 MarshalBinary 产生的 8 字节大端序数据。
+func (f *SnowflakeID) UnmarshalBinary(b []byte) error {
+	if len(b) != 8 {
+		return errors.New("snowflake: invalid binary length for SnowflakeID")
+	}
+	*f = SnowflakeID(binary.BigEndian.Uint64(b))
+	return nil
+}
+
+// Value 实现 database/sql/driver.Valuer，让 SnowflakeID 可以直接当主键列写入。
+func (f SnowflakeID) Value() (driver.Value, error) {
+	return int64(f), nil
+}
+
+// Scan 实现 database/sql.Scanner，兼容驱动把该列读成 int64、[]byte 或 string 的情况。
+func (f *SnowflakeID) Scan(value interface{}) error {
+	switch v := value.(type) {
+	case nil:
+		*f = 0
+		return nil
+	case int64:
+		*f = SnowflakeID(v)
+		return nil
+	case []byte:
+		id, err := ParseString(string(v))
+		if err != nil {
+			return err
+		}
+		*f = id
+		return nil
+	case string:
+		id, err := ParseString(v)
+		if err != nil {
+			return err
+		}
+		*f = id
+		return nil
+	default:
+		return fmt.Errorf("snowflake: unsupported Scan type %T for SnowflakeID", value)
+	}
+}
@@ -0,0 +1,95 @@
+package snowflake
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestSnowflakeIDEncodings(t *testing.T) {
+	instance, err := NewInstance(2, 1)
+	if err != nil {
+		t.Fatalf("new instance is error: %s", err.Error())
+	}
+
+	id, err := instance.NextID()
+	if err != nil {
+		t.Fatalf("next id is error: %s", err.Error())
+	}
+
+	if got, err := ParseString(id.String()); err != nil || got != id {
+		t.Fatalf("String round trip failed: got=%d err=%v", got, err)
+	}
+	if got, err := ParseBase2(id.Base2()); err != nil || got != id {
+		t.Fatalf("Base2 round trip failed: got=%d err=%v", got, err)
+	}
+	if got, err := ParseBase32(id.Base32()); err != nil || got != id {
+		t.Fatalf("Base32 round trip failed: got=%d err=%v", got, err)
+	}
+	if got, err := ParseBase58(id.Base58()); err != nil || got != id {
+		t.Fatalf("Base58 round trip failed: got=%d err=%v", got, err)
+	}
+	if got, err := ParseBase64(id.Base64()); err != nil || got != id {
+		t.Fatalf("Base64 round trip failed: got=%d err=%v", got, err)
+	}
+}
+
+func TestSnowflakeIDJSON(t *testing.T) {
+	id := SnowflakeID(9007199254740993) // 2^53 + 1，超出 JS Number 精度范围
+
+	b, err := json.Marshal(id)
+	if err != nil {
+		t.Fatalf("marshal is error: %s", err.Error())
+	}
+	if string(b) != `"9007199254740993"` {
+		t.Fatalf("expected quoted decimal string, got %s", string(b))
+	}
+
+	var got SnowflakeID
+	if err := json.Unmarshal(b, &got); err != nil {
+		t.Fatalf("unmarshal is error: %s", err.Error())
+	}
+	if got != id {
+		t.Fatalf("expected %d, got %d", id, got)
+	}
+}
+
+func TestSnowflakeIDBinaryRoundTrip(t *testing.T) {
+	id := SnowflakeID(123456789)
+
+	b, err := id.MarshalBinary()
+	if err != nil {
+		t.Fatalf("marshal binary is error: %s", err.Error())
+	}
+
+	var got SnowflakeID
+	if err := got.UnmarshalBinary(b); err != nil {
+		t.Fatalf("unmarshal binary is error: %s", err.Error())
+	}
+	if got != id {
+		t.Fatalf("expected %d, got %d", id, got)
+	}
+}
+
+func TestSnowflakeIDNegativeEncodingsDontPanic(t *testing.T) {
+	id := SnowflakeID(-5)
+
+	if got := id.Base32(); got == "" {
+		t.Fatalf("expected non-empty Base32 encoding for negative id, got %q", got)
+	}
+	if got := id.Base58(); got == "" {
+		t.Fatalf("expected non-empty Base58 encoding for negative id, got %q", got)
+	}
+}
+
+func TestSnowflakeIDScan(t *testing.T) {
+	var id SnowflakeID
+	if err := id.Scan(int64(42)); err != nil || id != 42 {
+		t.Fatalf("scan int64 failed: id=%d err=%v", id, err)
+	}
+	if err := id.Scan("43"); err != nil || id != 43 {
+		t.Fatalf("scan string failed: id=%d err=%v", id, err)
+	}
+	if err := id.Scan([]byte("44")); err != nil || id != 44 {
+		t.Fatalf("scan []byte failed: id=%d err=%v", id, err)
+	}
+}
@@ -0,0 +1,112 @@
+package snowflake
+
+import "errors"
+
+// negativeOne 用于通过位移构造各段的掩码：negativeOne ^ (negativeOne << n) 就是 n 个 1。
+const negativeOne int64 = -1
+
+// layoutTotalBits 是符号位之外可供时间戳/数据中心/机器/序列号四段分配的总位数。
+// 64 位的 int64 去掉最高的符号位（恒为 0，保证生成的 ID 为正数），剩下 63 位。
+const layoutTotalBits = 63
+
+// defaultEpoch 是仓库原来使用的起始时间戳（毫秒级），LayoutCurrent 沿用这个值
+// 以保持和旧版本生成的 ID 兼容。
+const defaultEpoch int64 = 1480166465631
+
+/**
+ * Layout 描述了一个 64 位 snowflake ID 里，时间戳、数据中心、机器标识、序列号
+ * 四段各占多少位，以及计算时间戳时使用的起始纪元（Epoch，毫秒级）。
+ *
+ * 四段位数之和必须等于 63（符号位固定占 1 位，不计入 Layout）。用 NewLayout 构造
+ * 才会做校验并预计算各段的掩码和位移；直接用字面量构造 Layout{} 不保证可用。
+ */
+type Layout struct {
+	TimestampBits  uint8
+	DataCenterBits uint8
+	MachineBits    uint8
+	SequenceBits   uint8
+	Epoch          int64
+
+	// 以下字段由 NewLayout 根据上面四个位宽预计算得到，调用方不需要关心。
+	maxSequence      int64
+	maxMachineNum    int64
+	maxDataCenterNum int64
+	maxTimestampNum  int64
+	machineLeft      uint8
+	dataCenterLeft   uint8
+	timestampLeft    uint8
+}
+
+// 内置的几个常用预设。
+var (
+	// LayoutTwitter 是 Twitter 官方 snowflake 参考实现使用的 41/5/5/12 划分，
+	// 支持 2^5=32 个数据中心、每个数据中心 2^5=32 台机器，单机每毫秒 4096 个序列号。
+	LayoutTwitter = mustLayout(41, 5, 5, 12, defaultEpoch)
+
+	// LayoutCurrent 是本仓库原来的 41/4/6/12 划分：16 个数据中心、每个数据中心 64 台机器。
+	LayoutCurrent = mustLayout(41, 4, 6, 12, defaultEpoch)
+
+	// LayoutHighConcurrency 牺牲部分数据中心/机器规模，换取单机每毫秒更高的并发序列号
+	// （2^16=65536/ms），适合机器数量不多但单机吞吐要求很高的场景。
+	LayoutHighConcurrency = mustLayout(37, 5, 5, 16, defaultEpoch)
+)
+
+// NewLayout 根据四段位宽和起始纪元构造一个 Layout，并校验位宽之和是否为 63。
+func NewLayout(timestampBits, dataCenterBits, machineBits, sequenceBits uint8, epoch int64) (Layout, error) {
+	l := Layout{
+		TimestampBits:  timestampBits,
+		DataCenterBits: dataCenterBits,
+		MachineBits:    machineBits,
+		SequenceBits:   sequenceBits,
+		Epoch:          epoch,
+	}
+
+	sum := int(timestampBits) + int(dataCenterBits) + int(machineBits) + int(sequenceBits)
+	if sum != layoutTotalBits {
+		return Layout{}, errors.New("snowflake: TimestampBits + DataCenterBits + MachineBits + SequenceBits must equal 63")
+	}
+
+	l.maxSequence = negativeOne ^ (negativeOne << l.SequenceBits)
+	l.maxMachineNum = negativeOne ^ (negativeOne << l.MachineBits)
+	l.maxDataCenterNum = negativeOne ^ (negativeOne << l.DataCenterBits)
+	l.maxTimestampNum = negativeOne ^ (negativeOne << l.TimestampBits)
+
+	l.machineLeft = l.SequenceBits
+	l.dataCenterLeft = l.SequenceBits + l.MachineBits
+	l.timestampLeft = l.dataCenterLeft + l.DataCenterBits
+
+	return l, nil
+}
+
+// mustLayout 和 NewLayout 一样，但构造失败时直接 panic，只用于构造包内置预设。
+func mustLayout(timestampBits, dataCenterBits, machineBits, sequenceBits uint8, epoch int64) Layout {
+	l, err := NewLayout(timestampBits, dataCenterBits, machineBits, sequenceBits, epoch)
+	if err != nil {
+		panic(err)
+	}
+	return l
+}
+
+/**
+ * ParseDataCenter 按当前 Layout 的位划分，从一个 id 里解析出 DataCenter 的十进制数。
+ * 必须使用生成该 id 时所用的 Layout 来解析，否则位宽/位移对不上会得到错误结果。
+ */
+func (l Layout) ParseDataCenter(id int64) int64 {
+	return (id & (l.maxDataCenterNum << l.dataCenterLeft)) >> l.dataCenterLeft
+}
+
+// ParseMachineId 按当前 Layout 的位划分，从一个 id 里解析出 Machine 的十进制数。
+func (l Layout) ParseMachineId(id int64) int64 {
+	return (id & (l.maxMachineNum << l.machineLeft)) >> l.machineLeft
+}
+
+// ParseDateTime 按当前 Layout 的位划分，从一个 id 里解析出生成时的毫秒级时间戳
+// （已经加回 Epoch，是真实的 unix 毫秒时间戳）。
+func (l Layout) ParseDateTime(id int64) int64 {
+	return ((id & (l.maxTimestampNum << l.timestampLeft)) >> l.timestampLeft) + l.Epoch
+}
+
+// ParseSequence 按当前 Layout 的位划分，从一个 id 里解析出 Sequence 的十进制数。
+func (l Layout) ParseSequence(id int64) int64 {
+	return id & l.maxSequence
+}
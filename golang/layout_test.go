@@ -0,0 +1,32 @@
+package snowflake
+
+import "testing"
+
+func TestNewLayoutRejectsBadBitWidths(t *testing.T) {
+	if _, err := NewLayout(41, 4, 6, 13, defaultEpoch); err == nil {
+		t.Fatalf("expected error when bit widths don't sum to 63")
+	}
+}
+
+func TestLayoutRoundTrip(t *testing.T) {
+	layouts := []Layout{LayoutTwitter, LayoutCurrent, LayoutHighConcurrency}
+
+	for _, layout := range layouts {
+		instance, err := NewInstance(1, 1, WithLayout(layout))
+		if err != nil {
+			t.Fatalf("new instance is error: %s", err.Error())
+		}
+
+		id, err := instance.NextId()
+		if err != nil {
+			t.Fatalf("next id is error: %s", err.Error())
+		}
+
+		if dc := layout.ParseDataCenter(id); dc != 1 {
+			t.Fatalf("expected data center 1, got %d", dc)
+		}
+		if m := layout.ParseMachineId(id); m != 1 {
+			t.Fatalf("expected machine id 1, got %d", m)
+		}
+	}
+}
@@ -0,0 +1,79 @@
+package server
+
+import (
+	"context"
+	"time"
+
+	snowflake "github.com/dxc520/snowflake/golang"
+	"google.golang.org/grpc"
+)
+
+// Streamer 是本地兜底实例需要满足的接口，snowflake.NewInstance 返回的实例的指针
+// 天然满足它（见 golang 包里的 Stream 方法）。
+type Streamer interface {
+	Stream(ctx context.Context, buf int) <-chan int64
+}
+
+/**
+ * Client 包装了一个 gRPC SnowflakeClient，正常情况下把 NextId 转发到远端服务；
+ * 一旦远端不可达或调用超时，透明地降级为本地的 fallback 分配器，由它的缓冲生成器
+ * （见 golang 包的 Stream）兜底出 id，保证调用方不中断。
+ *
+ * 本地兜底用的 DataCenterId/MachineId 必须和远端分配方案不重叠（比如远端固定用
+ * 0 号机器，所有 fallback 客户端各自配一个独有的 MachineId），否则网络恢复之后
+ * 客户端和服务端可能已经各自发出了重复的 id。
+ */
+type Client struct {
+	remote         SnowflakeClient
+	fallbackStream *snowflake.IDStream
+	fallbackCancel context.CancelFunc
+	callTimeout    time.Duration
+}
+
+// ClientOption 用于配置 NewClient。
+type ClientOption func(*Client)
+
+// WithCallTimeout 设置每次远端调用的超时时间，超时也会触发本地兜底。默认 500ms。
+func WithCallTimeout(d time.Duration) ClientOption {
+	return func(c *Client) {
+		c.callTimeout = d
+	}
+}
+
+// NewClient 用一个已建立的 *grpc.ClientConn 和一个本地兜底实例构造 Client。
+// fallback 通常应该用和远端分配方案不冲突的 (dataCenterId, machineId) 构造。
+func NewClient(cc *grpc.ClientConn, fallback Streamer, fallbackBuf int, opts ...ClientOption) *Client {
+	ctx, cancel := context.WithCancel(context.Background())
+	c := &Client{
+		remote:         NewSnowflakeClient(cc),
+		fallbackStream: snowflake.NewIDStream(fallback.Stream(ctx, fallbackBuf)),
+		fallbackCancel: cancel,
+		callTimeout:    500 * time.Millisecond,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// Close 停止本地兜底生成器的后台 goroutine。
+func (c *Client) Close() {
+	c.fallbackCancel()
+}
+
+// NextId 优先请求远端服务；远端不可达或超时时，退化为从本地缓冲生成器取一个 id。
+func (c *Client) NextId(ctx context.Context) (int64, error) {
+	callCtx, cancel := context.WithTimeout(ctx, c.callTimeout)
+	defer cancel()
+
+	resp, err := c.remote.NextId(callCtx, &NextIdRequest{})
+	if err == nil {
+		return resp.Id, nil
+	}
+
+	id, ok := c.fallbackStream.Next()
+	if !ok {
+		return 0, err
+	}
+	return id, nil
+}
@@ -0,0 +1,149 @@
+/**
+ * server 包把 snowflake 包里的分配器包装成一个可以被多语言客户端共用的网络服务，
+ * 同时提供 gRPC 和一个简单的 HTTP+JSON 接口，两者背后是同一个 Allocator。
+ */
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/dxc520/snowflake/golang"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	idsGenerated = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: "snowflake",
+		Name:      "ids_generated_total",
+		Help:      "Total number of ids generated by this allocator.",
+	})
+	sequenceExhausted = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: "snowflake",
+		Name:      "sequence_exhausted_total",
+		Help:      "Number of times the per-millisecond sequence was exhausted and the allocator had to move on to the next millisecond.",
+	})
+	clockRollbacks = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: "snowflake",
+		Name:      "clock_rollback_total",
+		Help:      "Number of NextId/BatchNextIds requests refused because the wall clock moved backwards. Rollbacks absorbed by the allocator's backward tolerance or logical clock fallback are not counted.",
+	})
+)
+
+func init() {
+	prometheus.MustRegister(idsGenerated, sequenceExhausted, clockRollbacks)
+}
+
+// ObserveSequenceExhausted 增加 sequenceExhausted 计数器，供 snowflake.WithSequenceExhaustedHook
+// 注册到具体的分配器实例上——server 包只看到 Allocator 接口，拿不到具体实例去挂钩子，
+// 所以由构造分配器的一方（例如 cmd/snowflaked）把这个函数传给 WithSequenceExhaustedHook。
+func ObserveSequenceExhausted() {
+	sequenceExhausted.Inc()
+}
+
+// Allocator 是 server 对底层 id 分配器所需要的最小接口（snowflake.NewInstance 返回的
+// 实例的指针天然满足这个接口），方便测试里替换假实现。
+type Allocator interface {
+	NextId() (int64, error)
+	BatchNextIds(n int) ([]int64, error)
+}
+
+// Snowflake 把一个 Allocator 和一个 snowflake.Layout（用于 Parse）包装成 gRPC/HTTP 服务。
+type Snowflake struct {
+	Allocator Allocator
+	Layout    snowflake.Layout
+}
+
+// NewSnowflake 构造一个 Snowflake 服务实例。
+func NewSnowflake(allocator Allocator, layout snowflake.Layout) *Snowflake {
+	return &Snowflake{Allocator: allocator, Layout: layout}
+}
+
+// NextId 实现 SnowflakeServer。
+func (s *Snowflake) NextId(ctx context.Context, req *NextIdRequest) (*NextIdResponse, error) {
+	id, err := s.Allocator.NextId()
+	if err != nil {
+		if errors.Is(err, snowflake.ErrClockMovedBackwards) {
+			clockRollbacks.Inc()
+		}
+		return nil, err
+	}
+	idsGenerated.Inc()
+	return &NextIdResponse{Id: id}, nil
+}
+
+// BatchNextIds 实现 SnowflakeServer。
+func (s *Snowflake) BatchNextIds(ctx context.Context, req *BatchNextIdsRequest) (*BatchNextIdsResponse, error) {
+	if req.Count <= 0 {
+		return nil, errors.New("snowflake: count must be greater than 0")
+	}
+	ids, err := s.Allocator.BatchNextIds(int(req.Count))
+	if err != nil {
+		if errors.Is(err, snowflake.ErrClockMovedBackwards) {
+			clockRollbacks.Inc()
+		}
+		return nil, err
+	}
+	idsGenerated.Add(float64(len(ids)))
+	return &BatchNextIdsResponse{Ids: ids}, nil
+}
+
+// Parse 实现 SnowflakeServer，按服务端当前的 Layout 反解析一个 id。
+func (s *Snowflake) Parse(ctx context.Context, req *ParseRequest) (*ParseResponse, error) {
+	return &ParseResponse{
+		DataCenterId: s.Layout.ParseDataCenter(req.Id),
+		MachineId:    s.Layout.ParseMachineId(req.Id),
+		Timestamp:    s.Layout.ParseDateTime(req.Id),
+		Sequence:     s.Layout.ParseSequence(req.Id),
+	}, nil
+}
+
+/**
+ * HTTPHandler 返回一个 http.Handler，把同样的三个操作通过简单的 JSON API 暴露出来，
+ * 供不方便接入 gRPC 的调用方使用：
+ *   GET  /next-id           -> {"id": 123}
+ *   GET  /batch-next-ids?n=100 -> {"ids": [123, ...]}
+ *   GET  /parse?id=123      -> {"data_center_id":.., "machine_id":.., "timestamp":.., "sequence":..}
+ */
+func (s *Snowflake) HTTPHandler() http.Handler {
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("/next-id", func(w http.ResponseWriter, r *http.Request) {
+		resp, err := s.NextId(r.Context(), &NextIdRequest{})
+		writeJSON(w, resp, err)
+	})
+
+	mux.HandleFunc("/batch-next-ids", func(w http.ResponseWriter, r *http.Request) {
+		n, err := strconv.Atoi(r.URL.Query().Get("n"))
+		if err != nil {
+			http.Error(w, "invalid n: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+		resp, err := s.BatchNextIds(r.Context(), &BatchNextIdsRequest{Count: int32(n)})
+		writeJSON(w, resp, err)
+	})
+
+	mux.HandleFunc("/parse", func(w http.ResponseWriter, r *http.Request) {
+		id, err := strconv.ParseInt(r.URL.Query().Get("id"), 10, 64)
+		if err != nil {
+			http.Error(w, "invalid id: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+		resp, err := s.Parse(r.Context(), &ParseRequest{Id: id})
+		writeJSON(w, resp, err)
+	})
+
+	return mux
+}
+
+func writeJSON(w http.ResponseWriter, body interface{}, err error) {
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(body)
+}
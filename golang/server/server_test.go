@@ -0,0 +1,203 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	snowflake "github.com/dxc520/snowflake/golang"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+type fakeAllocator struct {
+	nextID int64
+}
+
+func (f *fakeAllocator) NextId() (int64, error) {
+	f.nextID++
+	return f.nextID, nil
+}
+
+func (f *fakeAllocator) BatchNextIds(n int) ([]int64, error) {
+	ids := make([]int64, n)
+	for i := range ids {
+		f.nextID++
+		ids[i] = f.nextID
+	}
+	return ids, nil
+}
+
+// erroringAllocator 总是返回 snowflake.ErrClockMovedBackwards，用来验证
+// clockRollbacks 指标确实随这个错误增加。
+type erroringAllocator struct{}
+
+func (erroringAllocator) NextId() (int64, error) { return 0, snowflake.ErrClockMovedBackwards }
+func (erroringAllocator) BatchNextIds(n int) ([]int64, error) {
+	return nil, snowflake.ErrClockMovedBackwards
+}
+
+func TestHTTPHandlerNextId(t *testing.T) {
+	svc := NewSnowflake(&fakeAllocator{}, snowflake.LayoutCurrent)
+	before := testutil.ToFloat64(idsGenerated)
+
+	req := httptest.NewRequest("GET", "/next-id", nil)
+	w := httptest.NewRecorder()
+	svc.HTTPHandler().ServeHTTP(w, req)
+
+	if w.Code != 200 {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+
+	var body struct {
+		Id int64 `json:"id"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Fatalf("decode response: %s", err.Error())
+	}
+	if body.Id != 1 {
+		t.Fatalf("expected id 1, got %d", body.Id)
+	}
+	if got := testutil.ToFloat64(idsGenerated); got != before+1 {
+		t.Fatalf("expected idsGenerated to increase by 1, went %v -> %v", before, got)
+	}
+}
+
+func TestHTTPHandlerBatchNextIds(t *testing.T) {
+	svc := NewSnowflake(&fakeAllocator{}, snowflake.LayoutCurrent)
+	before := testutil.ToFloat64(idsGenerated)
+
+	req := httptest.NewRequest("GET", "/batch-next-ids?n=5", nil)
+	w := httptest.NewRecorder()
+	svc.HTTPHandler().ServeHTTP(w, req)
+
+	if w.Code != 200 {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+
+	var body struct {
+		Ids []int64 `json:"ids"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Fatalf("decode response: %s", err.Error())
+	}
+	if len(body.Ids) != 5 {
+		t.Fatalf("expected 5 ids, got %d", len(body.Ids))
+	}
+	if got := testutil.ToFloat64(idsGenerated); got != before+5 {
+		t.Fatalf("expected idsGenerated to increase by 5, went %v -> %v", before, got)
+	}
+}
+
+func TestHTTPHandlerParse(t *testing.T) {
+	svc := NewSnowflake(&fakeAllocator{}, snowflake.LayoutCurrent)
+
+	req := httptest.NewRequest("GET", "/parse?id=123456", nil)
+	w := httptest.NewRecorder()
+	svc.HTTPHandler().ServeHTTP(w, req)
+
+	if w.Code != 200 {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+
+	var body struct {
+		DataCenterId int64 `json:"data_center_id"`
+		MachineId    int64 `json:"machine_id"`
+		Timestamp    int64 `json:"timestamp"`
+		Sequence     int64 `json:"sequence"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Fatalf("decode response: %s", err.Error())
+	}
+	want := snowflake.LayoutCurrent
+	if body.DataCenterId != want.ParseDataCenter(123456) ||
+		body.MachineId != want.ParseMachineId(123456) ||
+		body.Timestamp != want.ParseDateTime(123456) ||
+		body.Sequence != want.ParseSequence(123456) {
+		t.Fatalf("parsed response does not match Layout.Parse*: %+v", body)
+	}
+}
+
+func TestNextIdIncrementsClockRollbacksOnClockMovedBackwards(t *testing.T) {
+	svc := NewSnowflake(erroringAllocator{}, snowflake.LayoutCurrent)
+	before := testutil.ToFloat64(clockRollbacks)
+
+	_, err := svc.NextId(context.Background(), &NextIdRequest{})
+	if !errors.Is(err, snowflake.ErrClockMovedBackwards) {
+		t.Fatalf("expected ErrClockMovedBackwards, got %v", err)
+	}
+	if got := testutil.ToFloat64(clockRollbacks); got != before+1 {
+		t.Fatalf("expected clockRollbacks to increase by 1, went %v -> %v", before, got)
+	}
+}
+
+// fakeClock 是一个可以被测试任意拨动的毫秒时钟，配合 snowflake.WithClockFunc 注入，
+// 用来把时间冻结在同一毫秒、逼出序列号耗尽，而不依赖真实墙钟的时序假设。
+type fakeClock struct {
+	mu  sync.Mutex
+	now int64
+}
+
+func (c *fakeClock) Now() int64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) Set(ms int64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = ms
+}
+
+func TestSequenceExhaustedHookIncrementsMetric(t *testing.T) {
+	before := testutil.ToFloat64(sequenceExhausted)
+
+	clock := &fakeClock{now: 1_700_000_000_000}
+	// exhausted 在钩子被调用时关闭；nextIdLocked 在同一把锁内先判定序列号回绕、
+	// 调用钩子，然后才进入 getNextMill 自旋等待下一毫秒，所以 <-exhausted 能确定
+	// 回绕确实发生在时钟被拨动之前，不依赖对 goroutine 调度时序的猜测。
+	exhausted := make(chan struct{})
+	instance, err := snowflake.NewInstance(1, 1,
+		snowflake.WithClockFunc(clock.Now),
+		snowflake.WithSequenceExhaustedHook(func() {
+			ObserveSequenceExhausted()
+			close(exhausted)
+		}),
+	)
+	if err != nil {
+		t.Fatalf("new instance is error: %s", err.Error())
+	}
+
+	// 时钟冻结不动，驱动 maxSequence+1 次调用只是在同一毫秒内把序列号从 0 走到
+	// maxSequence，还没有发生耗尽/回绕。
+	maxSequence := int(1<<instance.Layout.SequenceBits) - 1
+	for i := 0; i < maxSequence+1; i++ {
+		if _, err := instance.NextId(); err != nil {
+			t.Fatalf("next id %d is error: %s", i, err.Error())
+		}
+	}
+
+	// 再来一次调用会让序列号回绕到 0，触发 onSequenceExhausted 钩子，然后自旋等待
+	// 时钟前进（因为我们还没动时钟），所以放到 goroutine 里，等钩子确认回绕已经
+	// 发生之后再把时钟拨到下一毫秒，让它能够返回。
+	done := make(chan struct{})
+	var wrapErr error
+	go func() {
+		_, wrapErr = instance.NextId()
+		close(done)
+	}()
+
+	<-exhausted
+	clock.Set(clock.Now() + 1)
+	<-done
+	if wrapErr != nil {
+		t.Fatalf("wrap-around next id is error: %s", wrapErr.Error())
+	}
+
+	if got := testutil.ToFloat64(sequenceExhausted); got <= before {
+		t.Fatalf("expected sequenceExhausted to increase, went %v -> %v", before, got)
+	}
+}
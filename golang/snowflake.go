@@ -11,6 +11,10 @@
  * 设计时需要考虑的2点因素：
  *  1、 单机的并发量 即 单位毫秒内并发数:业即第五部分最大的容量 2^12=4096/ms，如果不满足，就的考虑 缩减，二、三、四部分，扩大 第五部分
  *  2、规格：服务实例的总体规模：也即 第二+第三部分的总和。即 2^10=1024.如果不满足，需要考虑这部分扩容，其余部分缩容
+ *
+ * 以上划分不再是写死的常量，而是 Layout（见 layout.go）里的一个预设 LayoutCurrent，
+ * 可以通过 NewInstance(..., WithLayout(...)) 换成 LayoutTwitter、LayoutHighConcurrency，
+ * 或者自己用 NewLayout 调整四段的位宽。
  */
 
 package snowflake
@@ -18,84 +22,141 @@ package snowflake
 import (
 	"errors"
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
-/**
- * 起始的时间戳 毫秒级
- */
-const start_timestamp int64 = 1480166465631
-
-/**
- * 每一部分占用的位数
- */
-const (
-	sequence_bit    uint8 = 12 // 序列号占用的位数 毫秒并发数 2^12=4096/ms;
-	machine_bit     uint8 = 6  // 机器标识占用的位数
-	data_center_bit uint8 = 4  // 数据中心占用的位数
-	timestamp_bit   uint8 = 41 // 毫秒时间占用的位数；
-	sign_bit        uint8 = 1  // 符号位，即首位一般不用，代表数字为正数
-)
-
-/**
- * 每一部分的最大值
- */
-
-const (
-	negativeOne         int64 = -1
-	max_sequence        int64 = negativeOne ^ (negativeOne << sequence_bit)
-	max_machine_num     int64 = negativeOne ^ (negativeOne << machine_bit)
-	max_data_center_num int64 = negativeOne ^ (negativeOne << data_center_bit)
-	max_timestamp_num   int64 = negativeOne ^ (negativeOne << timestamp_bit)
-)
-
-/**
- * 每一部分向左的位移
- */
-const (
-	machine_left     = sequence_bit
-	data_center_left = sequence_bit + machine_bit
-	timestamp_left   = data_center_left + data_center_bit
-)
-
 type snowFlakeId struct {
 	DataCenterId  int64 // 数据中心
 	MachineId     int64 // 机器标识
 	Sequence      int64 //= 0 // 序列号
 	LastTimeStamp int64 //= -1 // 上一次时间戳
+	Layout        Layout // 位分配方案，默认 LayoutCurrent，可用 WithLayout 替换
 	lock          sync.Mutex
+
+	// maxBackwardMillis 是允许的时钟回拨容忍值（毫秒）。回拨幅度在此范围内时，
+	// NextId 会自旋等待墙钟追上，而不是直接报错。0 表示不容忍，保持旧行为。
+	maxBackwardMillis int64
+	// logicalClock 为 true 时，一旦回拨超过 maxBackwardMillis，不再报错，
+	// 而是让 LastTimeStamp 按逻辑时钟继续自增（借用序列号位）：id 仍然唯一，
+	// 但其中的时间戳会领先于真实墙钟。
+	logicalClock bool
+
+	// clockFunc 返回当前毫秒时间戳；为 nil（默认）时 getNewTimeStamp 用下面基于单调时钟的偏移计算。
+	// 测试时可以用 WithClockFunc 注入一个可控的时钟。
+	clockFunc func() int64
+
+	// startWall/startMillis 用于在不依赖 clockFunc 时，基于构造时刻捕获的
+	// 单调时钟偏移计算当前时间戳，这样短暂的墙钟回跳（如 NTP 步进）不会影响 getNewTimeStamp。
+	startWall   time.Time
+	startMillis int64
+
+	// leaseLost 由 NewInstanceAuto 在协调后端判定 MachineId/DataCenterId 的租约
+	// 丢失后置为 1，此后 nextIdLocked 会拒绝继续签发 id。
+	leaseLost int32
+
+	// onSequenceExhausted 在同一毫秒内的序列号用尽、需要推进到下一毫秒
+	// （无论是等待墙钟还是逻辑时钟自增）时被调用，主要用于上报监控指标。
+	onSequenceExhausted func()
+}
+
+// ErrClockMovedBackwards 在时钟回拨且既没有落在 WithMaxBackwardMillis 容忍范围内、
+// 也没有开启 WithLogicalClockFallback 时返回，调用方可以用 errors.Is 识别这种情况。
+var ErrClockMovedBackwards = errors.New("Clock moved backwards.  Refusing to generate id")
+
+// Option 用于在 NewInstance 时配置 snowFlakeId 的可选行为。
+type Option func(*snowFlakeId)
+
+// WithMaxBackwardMillis 设置可容忍的时钟回拨毫秒数：回拨幅度不超过该值时，
+// NextId 会阻塞自旋等待墙钟追上，而不是立即返回错误。
+func WithMaxBackwardMillis(ms int64) Option {
+	return func(p *snowFlakeId) {
+		p.maxBackwardMillis = ms
+	}
+}
+
+// WithLogicalClockFallback 设置当回拨幅度超过 maxBackwardMillis 时的处理方式：
+// enabled 为 true 时退化为逻辑时钟（LastTimeStamp 继续自增、借用序列号位），
+// 而不是返回 Clock moved backwards 错误。
+func WithLogicalClockFallback(enabled bool) Option {
+	return func(p *snowFlakeId) {
+		p.logicalClock = enabled
+	}
+}
+
+// WithClockFunc 注入一个自定义的毫秒时钟函数，主要用于测试中模拟时钟回拨。
+func WithClockFunc(f func() int64) Option {
+	return func(p *snowFlakeId) {
+		p.clockFunc = f
+	}
+}
+
+// WithLayout 用指定的位分配方案替换默认的 LayoutCurrent，例如 LayoutTwitter、
+// LayoutHighConcurrency，或者 NewLayout 自己构造的方案。
+func WithLayout(layout Layout) Option {
+	return func(p *snowFlakeId) {
+		p.Layout = layout
+	}
+}
+
+// WithSequenceExhaustedHook 注册一个回调，在同一毫秒内的序列号用尽、
+// 需要推进到下一毫秒时触发，主要用于上报监控指标（见 server 包的 sequenceExhausted）。
+func WithSequenceExhaustedHook(hook func()) Option {
+	return func(p *snowFlakeId) {
+		p.onSequenceExhausted = hook
+	}
 }
 
 func (p *snowFlakeId) getNextMill() int64 {
 	mill := p.getNewTimeStamp()
-	for {
-		if mill <= p.LastTimeStamp {
-			mill = p.getNewTimeStamp()
-			break
-		}
+	for mill <= p.LastTimeStamp {
+		mill = p.getNewTimeStamp()
+	}
+	return mill
+}
+
+// waitForBackwardMillis 在发生小幅时钟回拨（未超过 maxBackwardMillis）时，
+// 自旋等待墙钟追上 LastTimeStamp，返回追上后的时间戳。
+func (p *snowFlakeId) waitForBackwardMillis() int64 {
+	mill := p.getNewTimeStamp()
+	for mill < p.LastTimeStamp {
+		mill = p.getNewTimeStamp()
 	}
 	return mill
 }
 
 func (p *snowFlakeId) getNewTimeStamp() int64 {
-	//return System.currentTimeMillis();
-	//return time.Now().Unix() //秒
-	//fmt.Printf("时间戳（纳秒转换为秒）：%v;\n",time.Now().UnixNano() / 1e9)
-	return time.Now().UnixNano() / 1e6 //毫秒
+	if p.clockFunc != nil {
+		return p.clockFunc()
+	}
+	// 基于构造时捕获的单调时钟差值推算当前毫秒时间戳，time.Since 使用的是
+	// 单调时钟读数，不受 NTP 步进等墙钟调整的影响。
+	return p.startMillis + time.Since(p.startWall).Milliseconds()
 }
 
 /**
  * 根据指定的数据中心ID和机器标志ID生成指定的序列号
  *
- * @param dataCenterId 数据中心ID(2^data_center_bit)=8
- * @param machineId    机器标志ID(2^machine_bit)=32
+ * @param dataCenterId 数据中心ID，上限由 Layout.DataCenterBits 决定，默认 LayoutCurrent 下是 2^4=16
+ * @param machineId    机器标志ID，上限由 Layout.MachineBits 决定，默认 LayoutCurrent 下是 2^6=64
+ * @param opts         可选配置，如 WithLayout、WithMaxBackwardMillis、WithClockFunc
  */
-func NewInstance(dataCenterId int64, machineId int64) (snowFlakeId, error) {
-	sf := snowFlakeId{Sequence: 0, LastTimeStamp: negativeOne}
-	if dataCenterId > max_data_center_num || dataCenterId < 0 {
+func NewInstance(dataCenterId int64, machineId int64, opts ...Option) (snowFlakeId, error) {
+	now := time.Now()
+	sf := snowFlakeId{
+		Sequence:      0,
+		LastTimeStamp: negativeOne,
+		Layout:        LayoutCurrent,
+		startWall:     now,
+		startMillis:   now.UnixNano() / 1e6,
+	}
+	for _, opt := range opts {
+		opt(&sf)
+	}
+	if dataCenterId > sf.Layout.maxDataCenterNum || dataCenterId < 0 {
 		return sf, errors.New("DtaCenterId can't be greater than MAX_DATA_CENTER_NUM or less than 0！");
 	}
-	if machineId > max_machine_num || machineId < 0 {
+	if machineId > sf.Layout.maxMachineNum || machineId < 0 {
 		return sf, errors.New("MachineId can't be greater than MAX_MACHINE_NUM or less than 0！");
 	}
 	sf.DataCenterId = dataCenterId
@@ -112,18 +173,51 @@ func (p *snowFlakeId) NextId() (int64, error) {
 	p.lock.Lock()
 	defer p.lock.Unlock()
 
+	return p.nextIdLocked()
+}
+
+// nextIdLocked 是 NextId 的核心逻辑，调用方必须持有 p.lock。
+// 拆出这个方法是为了让 BatchNextIds 能一次性加锁、循环生成多个 ID，
+// 而不必为每个 ID 都重新争抢一次互斥锁。
+func (p *snowFlakeId) nextIdLocked() (int64, error) {
+	if atomic.LoadInt32(&p.leaseLost) != 0 {
+		return 0, errors.New("snowflake: MachineId/DataCenterId lease lost, refusing to generate id")
+	}
+
 	currTimeStamp := p.getNewTimeStamp()
+	usingLogicalClock := false
 
 	if currTimeStamp < p.LastTimeStamp {
-		return 0, errors.New("Clock moved backwards.  Refusing to generate id");
+		backward := p.LastTimeStamp - currTimeStamp
+		switch {
+		case p.maxBackwardMillis > 0 && backward <= p.maxBackwardMillis:
+			// 回拨幅度在容忍范围内，自旋等待墙钟追上，而不是直接报错。
+			currTimeStamp = p.waitForBackwardMillis()
+		case p.logicalClock:
+			// 回拨超过容忍范围，退化为逻辑时钟：沿用 LastTimeStamp 继续签发，
+			// 序列号耗尽时下面会把 LastTimeStamp 向前推进 1ms（而不是等墙钟追上）。
+			currTimeStamp = p.LastTimeStamp
+			usingLogicalClock = true
+		default:
+			return 0, ErrClockMovedBackwards
+		}
 	}
 
 	if currTimeStamp == p.LastTimeStamp {
 		// 相同毫秒内，序列号自增
-		p.Sequence = (p.Sequence + 1) & max_sequence
+		p.Sequence = (p.Sequence + 1) & p.Layout.maxSequence
 		// 同一毫秒的序列数已经达到最大
 		if p.Sequence == 0 {
-			currTimeStamp = p.getNextMill()
+			if p.onSequenceExhausted != nil {
+				p.onSequenceExhausted()
+			}
+			if usingLogicalClock {
+				// 逻辑时钟模式下不等真实墙钟追上（那会无限期阻塞），而是直接把
+				// LastTimeStamp 向前推进 1ms，在这个虚拟的新毫秒里继续签发 id。
+				currTimeStamp++
+			} else {
+				currTimeStamp = p.getNextMill()
+			}
 		}
 	} else {
 		// 不同毫秒内，序列号置为0
@@ -133,49 +227,7 @@ func (p *snowFlakeId) NextId() (int64, error) {
 	p.LastTimeStamp = currTimeStamp
 
 	// 时间戳部分 |  数据中心部分 |  机器标识部分 |序列号部分
-	var nextId int64 = (currTimeStamp-start_timestamp)<<timestamp_left | p.DataCenterId<<data_center_left | p.MachineId<<machine_left | p.Sequence
+	var nextId int64 = (currTimeStamp-p.Layout.Epoch)<<p.Layout.timestampLeft | p.DataCenterId<<p.Layout.dataCenterLeft | p.MachineId<<p.Layout.machineLeft | p.Sequence
 
 	return nextId, nil
 }
-
-/// 以下为：已知snowflakeId，反解析为各个字段
-
-/**
- * 按段解析，获取DataCenter的十进制数
- * @param id
- * @return
- */
-func ParseDataCenter(id int64) int64 {
-	datacenterids := (id & (max_data_center_num << data_center_left)) >> data_center_left
-	return datacenterids
-}
-
-/**
- * 按段解析，获取Machine的十进制数
- * @param id
- * @return
- */
-func ParseMachineId(id int64) int64 {
-	machineId := (id & (max_machine_num << machine_left)) >> machine_left
-	return machineId
-}
-
-/**
- * 按段解析，获取DateTime的十进制数
- * @param id
- * @return
- */
-func ParseDateTime(id int64) int64 {
-	machineId := (id & (max_timestamp_num << timestamp_left)) >> timestamp_left
-	return machineId
-}
-
-/**
- * 按段解析，获取Sequence的十进制数
- * @param id
- * @return
- */
-func ParseSequence(id int64) int64 {
-	machineId := id & max_sequence
-	return machineId
-}
@@ -24,10 +24,10 @@ func TestMainSF(t *testing.T) {
 				fmt.Printf("get next id is error:=%s \n", err2.Error())
 			} else {
 
-				dateTimeStamp := ParseDateTime(nextId)
-				datacenterIds := ParseDataCenter(nextId)
-				machineId := ParseMachineId(nextId)
-				sequence := ParseSequence(nextId)
+				dateTimeStamp := instance.Layout.ParseDateTime(nextId)
+				datacenterIds := instance.Layout.ParseDataCenter(nextId)
+				machineId := instance.Layout.ParseMachineId(nextId)
+				sequence := instance.Layout.ParseSequence(nextId)
 
 				fmt.Printf("[%d]=%d ;it's timeStamp=%d,datacenterId=%d,machineId=%d,sequence=%d\n\n", idx+1, nextId, dateTimeStamp, datacenterIds, machineId, sequence)
 
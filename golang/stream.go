@@ -0,0 +1,79 @@
+package snowflake
+
+import (
+	"context"
+	"errors"
+)
+
+/**
+ * IDStream 包装了一个由后台 goroutine 持续填充的 ID 缓冲通道。
+ * 调用方通过 Next() 以 O(1) 的方式从缓冲区取 ID，避免每次都付出
+ * 互斥锁 + 系统调用（获取当前时间）的开销。
+ */
+type IDStream struct {
+	ch <-chan int64
+}
+
+// NewIDStream 用一个已有的 ID 通道构造出一个 IDStream，方便调用 Next()。
+func NewIDStream(ch <-chan int64) *IDStream {
+	return &IDStream{ch: ch}
+}
+
+// Next 从缓冲区取出下一个 ID；当底层通道已关闭且缓冲区已耗尽时，ok 为 false。
+func (s *IDStream) Next() (int64, bool) {
+	id, ok := <-s.ch
+	return id, ok
+}
+
+/**
+ * Stream 启动一个后台 goroutine，不断调用 NextId() 把生成的 ID 填充进一个
+ * 容量为 buf 的通道，返回给调用方消费。调用方可以直接从通道里收 ID（O(1)），
+ * 也可以用 NewIDStream 包一层拿到 Next() 语义。
+ *
+ * 当 ctx 被取消时，后台 goroutine 停止生产并关闭通道；当 NextId() 返回错误
+ * （例如时钟回拨且未配置容忍策略）时，同样视为结束，关闭通道退出。
+ */
+func (p *snowFlakeId) Stream(ctx context.Context, buf int) <-chan int64 {
+	ch := make(chan int64, buf)
+
+	go func() {
+		defer close(ch)
+		for {
+			id, err := p.NextId()
+			if err != nil {
+				return
+			}
+			select {
+			case ch <- id:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return ch
+}
+
+/**
+ * BatchNextIds 一次性加锁，在锁内循环生成最多 n 个 ID。
+ * 同一毫秒内最多能生成 Layout.SequenceBits 对应的序列号上限个 ID，超出后会在锁内自旋等待下一毫秒，
+ * 这样比反复调用 NextId()（每次都要重新加锁）吞吐更高。
+ */
+func (p *snowFlakeId) BatchNextIds(n int) ([]int64, error) {
+	if n <= 0 {
+		return nil, errors.New("n must be greater than 0")
+	}
+
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	ids := make([]int64, 0, n)
+	for len(ids) < n {
+		id, err := p.nextIdLocked()
+		if err != nil {
+			return ids, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
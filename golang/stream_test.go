@@ -0,0 +1,92 @@
+package snowflake
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestStreamShutdownOnContextDone(t *testing.T) {
+	instance, err := NewInstance(2, 1)
+	if err != nil {
+		t.Fatalf("new instance is error: %s", err.Error())
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	ch := instance.Stream(ctx, 8)
+	stream := NewIDStream(ch)
+
+	if _, ok := stream.Next(); !ok {
+		t.Fatalf("expected at least one id before cancel")
+	}
+
+	cancel()
+
+	// 通道应当最终被关闭，drain 完缓冲区之后 Next() 返回 ok=false。
+	deadline := time.After(time.Second)
+	for {
+		select {
+		case <-deadline:
+			t.Fatalf("stream did not shut down after ctx.Done()")
+		default:
+		}
+		if _, ok := stream.Next(); !ok {
+			return
+		}
+	}
+}
+
+func TestBatchNextIds(t *testing.T) {
+	instance, err := NewInstance(2, 1)
+	if err != nil {
+		t.Fatalf("new instance is error: %s", err.Error())
+	}
+
+	ids, err := instance.BatchNextIds(10000)
+	if err != nil {
+		t.Fatalf("batch next ids is error: %s", err.Error())
+	}
+	if len(ids) != 10000 {
+		t.Fatalf("expected 10000 ids, got %d", len(ids))
+	}
+
+	seen := make(map[int64]bool, len(ids))
+	for _, id := range ids {
+		if seen[id] {
+			t.Fatalf("duplicate id generated: %d", id)
+		}
+		seen[id] = true
+	}
+}
+
+func BenchmarkNextIdDirect(b *testing.B) {
+	instance, err := NewInstance(2, 1)
+	if err != nil {
+		b.Fatalf("new instance is error: %s", err.Error())
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := instance.NextId(); err != nil {
+			b.Fatalf("next id is error: %s", err.Error())
+		}
+	}
+}
+
+func BenchmarkNextIdStreamed(b *testing.B) {
+	instance, err := NewInstance(2, 1)
+	if err != nil {
+		b.Fatalf("new instance is error: %s", err.Error())
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	stream := NewIDStream(instance.Stream(ctx, 4096))
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, ok := stream.Next(); !ok {
+			b.Fatalf("stream closed unexpectedly")
+		}
+	}
+}